@@ -5,10 +5,12 @@ package main
 #define Py_LIMITED_API
 #include <Python.h>
 int PyArg_ParseTuple_S(PyObject *, char **);
+int PyArg_ParseTuple_O(PyObject *, PyObject **);
 */
 import "C"
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"image"
@@ -26,6 +28,7 @@ import (
 	_ "golang.org/x/image/webp"
 
 	"github.com/RobCherry/vibrant"
+	"github.com/brunomvsouza/dominantcolor"
 )
 
 // FromImageURI returns the dominant color (HEX format) of a given imageURI
@@ -71,6 +74,151 @@ func fromFile(file io.Reader) *C.PyObject {
 	return C.PyString_FromString(C.CString(""))
 }
 
+// FromImageURIPalette returns the full ranked palette of a given imageURI as
+// a list of (hex, weight) tuples, one per k-means cluster, sorted by
+// descending weight.
+//export FromImageURIPalette
+func FromImageURIPalette(self, args *C.PyObject) *C.PyObject {
+	var cImageURI *C.char
+	if C.PyArg_ParseTuple_S(args, &cImageURI) == 0 {
+		return C.PyList_New(0)
+	}
+
+	imageURI := C.GoString(cImageURI)
+
+	file, err := os.Open(imageURI)
+	defer file.Close()
+	if err != nil {
+		return C.PyList_New(0)
+	}
+
+	return paletteFromFile(file)
+}
+
+func paletteFromFile(file io.Reader) *C.PyObject {
+	image, _, err := image.Decode(file)
+	if err != nil {
+		return C.PyList_New(0)
+	}
+
+	swatches := dominantcolor.NewDefault().PaletteFromImage(image)
+	pyPalette := C.PyList_New(C.Py_ssize_t(len(swatches)))
+	for i, swatch := range swatches {
+		hexColor := rgbaToHex(&swatch.Color)
+		pair := newPyTuple2(
+			C.PyString_FromString(C.CString(hexColor)),
+			C.PyFloat_FromDouble(C.double(swatch.Population)),
+		)
+		C.PyList_SetItem(pyPalette, C.Py_ssize_t(i), pair)
+	}
+
+	return pyPalette
+}
+
+// newPyTuple2 builds a 2-tuple from two already-owned PyObject references.
+func newPyTuple2(a, b *C.PyObject) *C.PyObject {
+	tuple := C.PyTuple_New(2)
+	C.PyTuple_SetItem(tuple, 0, a)
+	C.PyTuple_SetItem(tuple, 1, b)
+	return tuple
+}
+
+// FromImageURISwatches returns the Vibrant/Muted family of named swatches
+// (HEX format) of a given imageURI, as a dict keyed by target name (e.g.
+// "vibrant", "dark-muted"). Targets for which no cluster matched are
+// omitted.
+//export FromImageURISwatches
+func FromImageURISwatches(self, args *C.PyObject) *C.PyObject {
+	var cImageURI *C.char
+	if C.PyArg_ParseTuple_S(args, &cImageURI) == 0 {
+		return C.PyDict_New()
+	}
+
+	imageURI := C.GoString(cImageURI)
+
+	file, err := os.Open(imageURI)
+	defer file.Close()
+	if err != nil {
+		return C.PyDict_New()
+	}
+
+	return swatchesFromFile(file)
+}
+
+func swatchesFromFile(file io.Reader) *C.PyObject {
+	image, _, err := image.Decode(file)
+	if err != nil {
+		return C.PyDict_New()
+	}
+
+	swatches := dominantcolor.NewDefault().Swatches(image)
+	pySwatches := C.PyDict_New()
+	for name, col := range swatches {
+		cName := C.CString(name)
+		hexColor := C.CString(rgbaToHex(&col))
+		value := C.PyString_FromString(hexColor)
+		// Unlike PyList_SetItem/PyTuple_SetItem above, PyDict_SetItemString
+		// does not steal the value reference, so it must be decref'd here.
+		C.PyDict_SetItemString(pySwatches, cName, value)
+		C.Py_DecRef(value)
+	}
+
+	return pySwatches
+}
+
+// FromImageURIBatch returns the dominant color (HEX format) of every path
+// in the given tuple, as a tuple of hex strings in the same order,
+// processing them concurrently instead of making Python callers loop one
+// at a time across the cgo boundary. It releases the GIL for the duration
+// of the batch so other Python threads can keep running.
+//export FromImageURIBatch
+func FromImageURIBatch(self, args *C.PyObject) *C.PyObject {
+	var cPaths *C.PyObject
+	if C.PyArg_ParseTuple_O(args, &cPaths) == 0 {
+		return C.PyTuple_New(0)
+	}
+
+	n := int(C.PySequence_Size(cPaths))
+	if n < 0 {
+		return C.PyTuple_New(0)
+	}
+
+	paths := make([]string, n)
+	for i := 0; i < n; i++ {
+		item := C.PySequence_GetItem(cPaths, C.Py_ssize_t(i))
+		if item == nil {
+			// Indexing failed; leave paths[i] empty and let the decode
+			// error for it surface through FromPaths instead.
+			C.PyErr_Clear()
+			continue
+		}
+		if cStr := C.PyString_AsString(item); cStr != nil {
+			paths[i] = C.GoString(cStr)
+		} else {
+			// item wasn't a string: PyString_AsString left an exception
+			// set that would otherwise corrupt the interpreter's error
+			// state for whatever runs next.
+			C.PyErr_Clear()
+		}
+		C.Py_DecRef(item)
+	}
+
+	threadState := C.PyEval_SaveThread()
+	results := dominantcolor.NewDefault().FromPaths(context.Background(), paths)
+	C.PyEval_RestoreThread(threadState)
+
+	pyResults := C.PyTuple_New(C.Py_ssize_t(n))
+	for i, result := range results {
+		hexColor := ""
+		if result.Err == nil {
+			hexColor = rgbaToHex(&result.Color)
+		}
+		C.PyTuple_SetItem(pyResults, C.Py_ssize_t(i), C.PyString_FromString(C.CString(hexColor)))
+	}
+
+	return pyResults
+}
+
 // FromBase64Image returns the dominant color (HEX format) of the given base64 image
 //export FromBase64Image
 func FromBase64Image(self, args *C.PyObject) *C.PyObject {
@@ -0,0 +1,133 @@
+package dominantcolor
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// ImageJob is one unit of work submitted to FromImages.
+type ImageJob struct {
+	// Index identifies the job so its Result can be matched back up once
+	// results start arriving out of submission order, and seeds that job's
+	// worker for deterministic output regardless of scheduling.
+	Index int
+	Image image.Image
+}
+
+// Result is what FromImages/FromPaths produces for one ImageJob.
+type Result struct {
+	Index int
+	Color color.RGBA
+	Err   error
+}
+
+// FromImages runs FromImage over imgs concurrently, using a pool of
+// d.Concurrency workers (runtime.GOMAXPROCS(0) if unset). Each worker gets
+// its own copy of d seeded with the job's Index, so results are
+// reproducible independent of how work happens to interleave across
+// goroutines. The returned channel is closed once imgs is drained (or ctx
+// is done) and every in-flight job has finished.
+func (d *DominantColor) FromImages(ctx context.Context, imgs <-chan ImageJob) <-chan Result {
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	out := make(chan Result)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job, ok := <-imgs:
+					if !ok {
+						return
+					}
+					worker := *d
+					worker.Seed = int64(job.Index)
+					result := Result{Index: job.Index, Color: worker.FromImage(job.Image)}
+					select {
+					case out <- result:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// FromPaths decodes and processes every path concurrently, the same way
+// FromImages does, and returns one Result per path in input order
+// regardless of completion order. A path that fails to open or decode gets
+// a Result with Err set and a zero Color.
+func (d *DominantColor) FromPaths(ctx context.Context, paths []string) []Result {
+	jobs := make(chan ImageJob)
+	decodeErrs := make([]error, len(paths))
+
+	go func() {
+		defer close(jobs)
+		for i, path := range paths {
+			img, err := decodeFile(path)
+			if err != nil {
+				decodeErrs[i] = err
+				continue
+			}
+			select {
+			case jobs <- ImageJob{Index: i, Image: img}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]Result, len(paths))
+	filled := make([]bool, len(paths))
+	for r := range d.FromImages(ctx, jobs) {
+		results[r.Index] = r
+		filled[r.Index] = true
+	}
+	for i, err := range decodeErrs {
+		if err != nil {
+			results[i] = Result{Index: i, Err: err}
+			filled[i] = true
+		}
+	}
+	// ctx may have been cancelled before every path got a Result (the
+	// producer or a worker bailed out mid-job); fill any slot that's still
+	// unresolved instead of leaving a zero Result indistinguishable from a
+	// successful, all-black decode.
+	if err := ctx.Err(); err != nil {
+		for i, ok := range filled {
+			if !ok {
+				results[i] = Result{Index: i, Err: err}
+			}
+		}
+	}
+	return results
+}
+
+func decodeFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	return img, err
+}
@@ -0,0 +1,77 @@
+package dominantcolor
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// solidImages builds n small uniform-colored images, one per color so each
+// job's expected result is unambiguous regardless of scheduling order.
+func solidImages(colors []color.RGBA) []image.Image {
+	imgs := make([]image.Image, len(colors))
+	for i, col := range colors {
+		img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				img.Set(x, y, col)
+			}
+		}
+		imgs[i] = img
+	}
+	return imgs
+}
+
+func collectResults(t *testing.T, d *DominantColor, imgs []image.Image) []Result {
+	t.Helper()
+	jobs := make(chan ImageJob)
+	go func() {
+		defer close(jobs)
+		for i, img := range imgs {
+			jobs <- ImageJob{Index: i, Image: img}
+		}
+	}()
+
+	results := make([]Result, len(imgs))
+	for r := range d.FromImages(context.Background(), jobs) {
+		results[r.Index] = r
+	}
+	return results
+}
+
+// TestFromImagesDeterministic checks the concurrency claim in FromImages'
+// doc comment: each worker is seeded from its job's Index, so the result
+// for a given image is the same regardless of how many workers raced to
+// process it.
+func TestFromImagesDeterministic(t *testing.T) {
+	colors := []color.RGBA{
+		{R: 200, G: 40, B: 40, A: 255},
+		{R: 40, G: 200, B: 40, A: 255},
+		{R: 40, G: 40, B: 200, A: 255},
+		{R: 200, G: 200, B: 40, A: 255},
+	}
+	imgs := solidImages(colors)
+
+	base := DominantColor{
+		SampleImageSize:          256,
+		NumberOfClusters:         2,
+		UniqueColorSearchRetries: 10,
+		ConvergenceIterations:    50,
+	}
+
+	sequential := base
+	sequential.Concurrency = 1
+	sequentialResults := collectResults(t, &sequential, imgs)
+
+	parallel := base
+	parallel.Concurrency = 8
+	parallelResults := collectResults(t, &parallel, imgs)
+
+	for i := range colors {
+		if sequentialResults[i].Color != parallelResults[i].Color {
+			t.Errorf("index %d: Concurrency=1 got %v, Concurrency=8 got %v",
+				i, sequentialResults[i].Color, parallelResults[i].Color)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2011 The Chromium Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package dominantcolor
+
+import "math"
+
+// kMeanCluster is a single cluster in RGB space, ported from Chromium's
+// KMeanCluster (see color_analysis.cc). It keeps a running sum of the
+// points assigned to it during an iteration so that RecomputeCentroid can
+// derive the new centroid without a second pass over the image.
+type kMeanCluster struct {
+	centroidR, centroidG, centroidB uint8
+
+	// meanR, meanG, meanB is the same center as centroidR/G/B but kept at
+	// full precision, i.e. before it's rounded down to uint8. Recenter and
+	// RecomputeCentroid both refresh it from the aggregate before that
+	// aggregate is consumed, so it survives past the reset that
+	// RecomputeCentroid does for the next iteration's rescan.
+	meanR, meanG, meanB float64
+
+	aggregateR, aggregateG, aggregateB uint64
+	counter                            uint32
+
+	// weight is the number of points that were assigned to this cluster the
+	// last time RecomputeCentroid was called.
+	weight int
+}
+
+// SetCentroid sets the initial center of the cluster.
+func (c *kMeanCluster) SetCentroid(r, g, b uint8) {
+	c.centroidR, c.centroidG, c.centroidB = r, g, b
+	c.meanR, c.meanG, c.meanB = float64(r), float64(g), float64(b)
+}
+
+// newFixedCluster wraps an already-computed color and weight, such as a
+// bucket from a quantize.Quantizer or a converted-back-from-LAB centroid,
+// as a kMeanCluster so it can flow through the same weight-sort and
+// threshold selection as a k-means fit. These sources hand back an
+// already-quantized color with no sub-pixel remainder to keep, so Mean
+// reports the same value as Centroid.
+func newFixedCluster(r, g, b uint8, weight int) *kMeanCluster {
+	c := &kMeanCluster{weight: weight}
+	c.SetCentroid(r, g, b)
+	return c
+}
+
+// Centroid returns the current center of the cluster, rounded to uint8.
+func (c *kMeanCluster) Centroid() (r, g, b uint8) {
+	return c.centroidR, c.centroidG, c.centroidB
+}
+
+// Mean returns the current center of the cluster at full float64
+// precision, i.e. before Centroid's uint8 rounding.
+func (c *kMeanCluster) Mean() (r, g, b float64) {
+	return c.meanR, c.meanG, c.meanB
+}
+
+// distanceSqr returns the squared Euclidean distance in RGB space between
+// the cluster's centroid and the given point.
+func (c *kMeanCluster) distanceSqr(r, g, b uint8) int {
+	dr := int(c.centroidR) - int(r)
+	dg := int(c.centroidG) - int(g)
+	db := int(c.centroidB) - int(b)
+	return dr*dr + dg*dg + db*db
+}
+
+// AddPoint adds a point to this cluster's running aggregate.
+func (c *kMeanCluster) AddPoint(r, g, b uint8) {
+	c.aggregateR += uint64(r)
+	c.aggregateG += uint64(g)
+	c.aggregateB += uint64(b)
+	c.counter++
+}
+
+// RemovePoint undoes a prior AddPoint, used when a point is reassigned to a
+// different cluster mid-fit instead of being rebuilt from a full rescan.
+func (c *kMeanCluster) RemovePoint(r, g, b uint8) {
+	c.aggregateR -= uint64(r)
+	c.aggregateG -= uint64(g)
+	c.aggregateB -= uint64(b)
+	if c.counter > 0 {
+		c.counter--
+	}
+}
+
+// Recenter moves the centroid to the mean of the current aggregate, without
+// resetting it, and returns how far the centroid moved. Unlike
+// RecomputeCentroid it assumes the aggregate is kept up to date
+// incrementally via AddPoint/RemovePoint rather than rebuilt from scratch
+// every iteration.
+func (c *kMeanCluster) Recenter() float64 {
+	c.weight = int(c.counter)
+	if c.counter == 0 {
+		return 0
+	}
+	n := float64(c.counter)
+	c.meanR = float64(c.aggregateR) / n
+	c.meanG = float64(c.aggregateG) / n
+	c.meanB = float64(c.aggregateB) / n
+	newR := uint8(c.aggregateR / uint64(c.counter))
+	newG := uint8(c.aggregateG / uint64(c.counter))
+	newB := uint8(c.aggregateB / uint64(c.counter))
+	dr := int(newR) - int(c.centroidR)
+	dg := int(newG) - int(c.centroidG)
+	db := int(newB) - int(c.centroidB)
+	c.centroidR, c.centroidG, c.centroidB = newR, newG, newB
+	return math.Sqrt(float64(dr*dr + dg*dg + db*db))
+}
+
+// RecomputeCentroid derives the new centroid from the aggregated points and
+// resets the aggregate for the next iteration.
+func (c *kMeanCluster) RecomputeCentroid() {
+	c.weight = int(c.counter)
+	if c.counter != 0 {
+		n := float64(c.counter)
+		c.meanR = float64(c.aggregateR) / n
+		c.meanG = float64(c.aggregateG) / n
+		c.meanB = float64(c.aggregateB) / n
+		c.centroidR = uint8(c.aggregateR / uint64(c.counter))
+		c.centroidG = uint8(c.aggregateG / uint64(c.counter))
+		c.centroidB = uint8(c.aggregateB / uint64(c.counter))
+	}
+	c.aggregateR, c.aggregateG, c.aggregateB, c.counter = 0, 0, 0, 0
+}
+
+// CompareCentroidWithAggregate reports whether the centroid computed from
+// the current aggregate matches the existing centroid, i.e. whether this
+// cluster has converged.
+func (c *kMeanCluster) CompareCentroidWithAggregate() bool {
+	if c.counter == 0 {
+		return true
+	}
+	return c.centroidR == uint8(c.aggregateR/uint64(c.counter)) &&
+		c.centroidG == uint8(c.aggregateG/uint64(c.counter)) &&
+		c.centroidB == uint8(c.aggregateB/uint64(c.counter))
+}
+
+// kMeanClusterGroup is a set of clusters being fitted together.
+type kMeanClusterGroup []*kMeanCluster
+
+// ContainsCentroid reports whether any cluster in the group is already
+// centered on the given color.
+func (grp kMeanClusterGroup) ContainsCentroid(r, g, b uint8) bool {
+	for _, c := range grp {
+		if c.centroidR == r && c.centroidG == g && c.centroidB == b {
+			return true
+		}
+	}
+	return false
+}
+
+// Closest returns the cluster whose centroid is nearest to the given point
+// in RGB space.
+func (grp kMeanClusterGroup) Closest(r, g, b uint8) *kMeanCluster {
+	var closest *kMeanCluster
+	best := 0
+	for _, c := range grp {
+		if d := c.distanceSqr(r, g, b); closest == nil || d < best {
+			closest, best = c, d
+		}
+	}
+	return closest
+}
+
+// TotalWeight returns the sum of all cluster weights, i.e. the number of
+// non-transparent pixels that were sampled.
+func (grp kMeanClusterGroup) TotalWeight() int {
+	total := 0
+	for _, c := range grp {
+		total += c.weight
+	}
+	return total
+}
+
+// byWeight sorts a kMeanClusterGroup by descending weight (population).
+type byWeight kMeanClusterGroup
+
+func (a byWeight) Len() int      { return len(a) }
+func (a byWeight) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byWeight) Less(i, j int) bool {
+	return a[i].weight > a[j].weight
+}
@@ -0,0 +1,56 @@
+package dominantcolor
+
+import (
+	"image"
+	"sort"
+)
+
+// clusterLAB fits clusters the same way clusterNaive does, except distance
+// and centroid averaging happen in CIELAB space instead of RGB, so two
+// hues of similar raw RGB magnitude but very different perceived brightness
+// (e.g. pure yellow vs. pure blue) aren't treated as equally "bright" or
+// pulled toward the same muddy mean. Seeds are picked in RGB, exactly as
+// for the other algorithms, and only then converted to LAB.
+func (d *DominantColor) clusterLAB(img image.Image) kMeanClusterGroup {
+	seeds, img := d.seedClusters(img)
+
+	labClusters := make(labClusterGroup, 0, len(seeds))
+	for _, c := range seeds {
+		r, g, b := c.Centroid()
+		l, a, bb := rgbToLab(r, g, b)
+		lc := new(labCluster)
+		lc.SetCentroid(l, a, bb)
+		labClusters = append(labClusters, lc)
+	}
+
+	bounds := img.Bounds()
+	convergence := false
+	for i := 0; i < d.ConvergenceIterations && !convergence && len(labClusters) != 0; i++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				ri, gi, bi, alpha := img.At(x, y).RGBA()
+				// Ignore transparent pixels.
+				if alpha == 0 {
+					continue
+				}
+				r, g, b := uint8(ri/255), uint8(gi/255), uint8(bi/255)
+				l, a, bb := rgbToLab(r, g, b)
+				closest := labClusters.Closest(l, a, bb)
+				closest.AddPoint(l, a, bb)
+			}
+		}
+		convergence = true
+		for _, c := range labClusters {
+			converged := c.RecomputeCentroid()
+			convergence = convergence && converged
+		}
+	}
+	sort.Sort(byLabWeight(labClusters))
+
+	clusters := make(kMeanClusterGroup, 0, len(labClusters))
+	for _, c := range labClusters {
+		r, g, b := labToRGB(c.l, c.a, c.b)
+		clusters = append(clusters, newFixedCluster(r, g, b, c.weight))
+	}
+	return clusters
+}
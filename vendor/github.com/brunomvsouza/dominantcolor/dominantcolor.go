@@ -47,34 +47,132 @@ package dominantcolor
 import (
 	"image"
 	"image/color"
+	"math"
 	"math/rand"
 	"sort"
 
 	"github.com/nfnt/resize"
+
+	"github.com/brunomvsouza/dominantcolor/quantize"
 )
 
 type DominantColor struct {
-	SampleImageSize            uint
-	NumberOfClusters           int
-	UniqueColorSearchRetries   int
-	ConvergenceIterations      int
-	MaximumBrightnessThreshold uint16
-	MaximumDarknessThreshold   uint16
+	SampleImageSize          uint
+	NumberOfClusters         int
+	UniqueColorSearchRetries int
+	ConvergenceIterations    int
+
+	// MinHSP and MaxHSP gate which cluster FromImage returns by Finley's
+	// perceived-brightness formula sqrt(0.299*R²+0.587*G²+0.114*B²), each
+	// in [0, 255]. This replaces summing raw RGB channels, which rates
+	// saturated hues like pure blue as "dark" and pure yellow as "bright"
+	// regardless of how they actually look.
+	MinHSP, MaxHSP float64
+
+	// Targets configures the named swatches returned by Swatches. If nil,
+	// DefaultTargets is used.
+	Targets []Target
+
+	// Algorithm selects the k-means fitting strategy. The zero value uses
+	// AlgorithmElkan; AlgorithmNaive is kept around so the original
+	// brute-force behavior stays available for regression testing. Ignored
+	// when ColorSpace is ColorSpaceLAB or Quantizer is set.
+	Algorithm string
+
+	// ColorSpace selects the space k-means distance and centroid averaging
+	// are computed in. The zero value is ColorSpaceRGB; ColorSpaceLAB
+	// clusters by CIELAB ΔE for perceptually-uniform results. Centroids are
+	// always returned as sRGB regardless of ColorSpace.
+	ColorSpace string
+
+	// Quantizer, if set, replaces the k-means fit entirely with a
+	// single-pass color quantizer (see the quantize subpackage). Algorithm
+	// and ColorSpace are ignored when Quantizer is set.
+	Quantizer quantize.Quantizer
+
+	// Seed picks which starting pixels the cluster seeding step samples.
+	// FromImages/FromPaths set this to each job's index so a batch is
+	// deterministic regardless of how goroutines interleave.
+	Seed int64
+
+	// Concurrency caps how many images FromImages/FromPaths process at
+	// once. The zero value uses runtime.GOMAXPROCS(0).
+	Concurrency int
 }
 
-func (d *DominantColor) FromImage(img image.Image) color.RGBA {
-	// Shrink image for faster processing.
+// The two color spaces available via DominantColor.ColorSpace.
+const (
+	ColorSpaceRGB = "rgb"
+	ColorSpaceLAB = "lab"
+)
+
+// The two k-means fitting strategies available via DominantColor.Algorithm.
+const (
+	AlgorithmElkan = "elkan"
+	AlgorithmNaive = "naive"
+)
+
+// cluster fits clusters with the strategy d selects (Quantizer, the LAB fit,
+// or the RGB k-means fit described in the package doc comment) and returns
+// the resulting clusters sorted by descending population.
+func (d *DominantColor) cluster(img image.Image) kMeanClusterGroup {
+	if d.Quantizer != nil {
+		return d.clusterQuantize(img)
+	}
+	if d.ColorSpace == ColorSpaceLAB {
+		return d.clusterLAB(img)
+	}
+	if d.Algorithm == AlgorithmNaive {
+		return d.clusterNaive(img)
+	}
+	return d.clusterElkan(img)
+}
+
+// clusterQuantize samples every non-transparent pixel and hands them to
+// d.Quantizer, wrapping the resulting buckets as a kMeanClusterGroup so
+// FromImage/PaletteFromImage/Swatches can select among them exactly as
+// they would clusters from a k-means fit.
+func (d *DominantColor) clusterQuantize(img image.Image) kMeanClusterGroup {
+	img = resize.Thumbnail(d.SampleImageSize, d.SampleImageSize, img, resize.NearestNeighbor)
+
+	bounds := img.Bounds()
+	pixels := make([]quantize.Color, 0, bounds.Dx()*bounds.Dy())
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			ri, gi, bi, a := img.At(x, y).RGBA()
+			// Ignore transparent pixels.
+			if a == 0 {
+				continue
+			}
+			pixels = append(pixels, quantize.Color{R: uint8(ri / 255), G: uint8(gi / 255), B: uint8(bi / 255)})
+		}
+	}
+
+	buckets := d.Quantizer.Quantize(pixels, d.NumberOfClusters)
+	clusters := make(kMeanClusterGroup, 0, len(buckets))
+	for _, bucket := range buckets {
+		clusters = append(clusters, newFixedCluster(bucket.R, bucket.G, bucket.B, bucket.Count))
+	}
+	sort.Sort(byWeight(clusters))
+	return clusters
+}
+
+// seedClusters shrinks img for faster processing and picks one starting
+// centroid per cluster by randomly sampling unique, non-transparent pixel
+// colors, exactly as described in step 1 of the package doc comment. It is
+// shared by every clustering strategy so they start from identical seeds.
+func (d *DominantColor) seedClusters(img image.Image) (kMeanClusterGroup, image.Image) {
 	img = resize.Thumbnail(d.SampleImageSize, d.SampleImageSize, img, resize.NearestNeighbor)
 
 	bounds := img.Bounds()
 	width, height := bounds.Dx(), bounds.Dy()
-	rnd := rand.New(rand.NewSource(0))
+	rnd := rand.New(rand.NewSource(d.Seed))
 	randomPoint := func() (x, y int) {
 		x = bounds.Min.X + rnd.Intn(width)
 		y = bounds.Min.Y + rnd.Intn(height)
 		return
 	}
-	// Pick a starting point for each cluster.
+
 	clusters := make(kMeanClusterGroup, 0, d.NumberOfClusters)
 	for i := 0; i < d.NumberOfClusters; i++ {
 		// Try up to 10 times to find a unique color. If no unique color can be
@@ -102,6 +200,15 @@ func (d *DominantColor) FromImage(img image.Image) color.RGBA {
 			break
 		}
 	}
+	return clusters, img
+}
+
+// clusterNaive fits clusters by scanning every pixel against every cluster
+// on every iteration (Chromium's original approach).
+func (d *DominantColor) clusterNaive(img image.Image) kMeanClusterGroup {
+	clusters, img := d.seedClusters(img)
+	bounds := img.Bounds()
+
 	convergence := false
 	for i := 0; i < d.ConvergenceIterations && !convergence && len(clusters) != 0; i++ {
 		for x := bounds.Min.X; x < bounds.Max.X; x++ {
@@ -127,15 +234,21 @@ func (d *DominantColor) FromImage(img image.Image) color.RGBA {
 	// Sort the clusters by population so we can tell what the most popular
 	// color is.
 	sort.Sort(byWeight(clusters))
+	return clusters
+}
+
+func (d *DominantColor) FromImage(img image.Image) color.RGBA {
+	clusters := d.cluster(img)
 	// Loop through the clusters to figure out which cluster has an appropriate
 	// color. Skip any that are too bright/dark and go in order of weight.
 	var col color.RGBA
 	for i, c := range clusters {
 		r, g, b := c.Centroid()
-		// Sum the RGB components to determine if the color is too bright or too dark.
-		summedColor := uint16(r) + uint16(g) + uint16(b)
+		// Use perceived brightness rather than a raw RGB sum, so saturated
+		// hues aren't misjudged as too dark or too bright.
+		brightness := hsp(r, g, b)
 
-		if summedColor < d.MaximumBrightnessThreshold && summedColor > d.MaximumDarknessThreshold {
+		if brightness > d.MinHSP && brightness < d.MaxHSP {
 			// If we found a valid color just set it and break. We don't want to
 			// check the other ones.
 			col.R = r
@@ -155,30 +268,97 @@ func (d *DominantColor) FromImage(img image.Image) color.RGBA {
 	return col
 }
 
+// hsp returns Finley's perceived-brightness value for an sRGB color, in
+// the range [0, 255]. See http://alienryderflex.com/hsp.html.
+func hsp(r, g, b uint8) float64 {
+	rf, gf, bf := float64(r), float64(g), float64(b)
+	return math.Sqrt(0.299*rf*rf + 0.587*gf*gf + 0.114*bf*bf)
+}
+
+// Swatch is one cluster produced by the k-means fit, described by the
+// mean color of the pixels assigned to it and its relative weight.
+type Swatch struct {
+	Color color.RGBA
+	// Population is the fraction of sampled, non-transparent pixels that
+	// belong to this cluster, in the range [0, 1].
+	Population float64
+	// Centroid is the cluster's center in RGB space, at full float64
+	// precision rather than Color's uint8 rounding, so callers can do
+	// further math on it without re-quantizing. This only holds real
+	// sub-pixel precision for the RGB k-means fits (AlgorithmElkan and
+	// AlgorithmNaive); the LAB fit rounds to sRGB before a cluster reaches
+	// this struct, and a Quantizer bucket has no finer precision to begin
+	// with, so for those two Centroid equals Color's components exactly.
+	Centroid [3]float64
+}
+
+// PaletteFromImage returns every cluster found by the k-means fit, sorted
+// by descending population, unlike FromImage which only returns the single
+// best match against the brightness/darkness thresholds.
+func (d *DominantColor) PaletteFromImage(img image.Image) []Swatch {
+	clusters := d.cluster(img)
+	total := clusters.TotalWeight()
+	palette := make([]Swatch, 0, len(clusters))
+	for _, c := range clusters {
+		r, g, b := c.Centroid()
+		mr, mg, mb := c.Mean()
+		var population float64
+		if total > 0 {
+			population = float64(c.weight) / float64(total)
+		}
+		palette = append(palette, Swatch{
+			Color:      color.RGBA{R: r, G: g, B: b, A: 0xFF},
+			Population: population,
+			Centroid:   [3]float64{mr, mg, mb},
+		})
+	}
+	return palette
+}
+
 // NewDefault creates a new instance of DominantColor with
 // default settings
 func NewDefault() *DominantColor {
 	return &DominantColor{
-		SampleImageSize:            256,
-		NumberOfClusters:           4,
-		UniqueColorSearchRetries:   10,
-		ConvergenceIterations:      50,
-		MaximumBrightnessThreshold: 665,
-		MaximumDarknessThreshold:   100,
+		SampleImageSize:          256,
+		NumberOfClusters:         4,
+		UniqueColorSearchRetries: 10,
+		ConvergenceIterations:    50,
+		MinHSP:                   30,
+		MaxHSP:                   240,
 	}
 }
 
-// New creates a new instance of DominantColor
+// New creates a new instance of DominantColor using the pre-HSP brightness
+// thresholds, which were raw sums of the RGB channels in [0, 765].
+//
+// Deprecated: the brightness gate now compares Finley's perceived-brightness
+// value (see MinHSP/MaxHSP), which is scaled to [0, 255] instead of [0,
+// 765], so a raw RGB sum does not mean the same thing it used to. New
+// divides its arguments by 3 to land back in the HSP-ish range (the same
+// scaling a raw channel sum needs to approximate a single channel's
+// magnitude); this approximates the old gate but does not reproduce it
+// exactly, since the two formulas weigh channels differently. Use
+// NewWithHSP to set MinHSP/MaxHSP directly instead of relying on this
+// approximation.
 func New(sampleImageSize uint, numberOfClusters, uniqueColorSearchRetries,
 	convergenceIterations int, maximumBrightnessThreshold,
 	maximumDarknessThreshold uint16) *DominantColor {
 
+	return NewWithHSP(sampleImageSize, numberOfClusters, uniqueColorSearchRetries,
+		convergenceIterations, float64(maximumDarknessThreshold)/3, float64(maximumBrightnessThreshold)/3)
+}
+
+// NewWithHSP creates a new instance of DominantColor with MinHSP/MaxHSP set
+// directly on the perceived-brightness scale described on DominantColor.
+func NewWithHSP(sampleImageSize uint, numberOfClusters, uniqueColorSearchRetries,
+	convergenceIterations int, minHSP, maxHSP float64) *DominantColor {
+
 	return &DominantColor{
-		SampleImageSize:            sampleImageSize,
-		NumberOfClusters:           numberOfClusters,
-		UniqueColorSearchRetries:   uniqueColorSearchRetries,
-		ConvergenceIterations:      convergenceIterations,
-		MaximumBrightnessThreshold: maximumBrightnessThreshold,
-		MaximumDarknessThreshold:   maximumDarknessThreshold,
+		SampleImageSize:          sampleImageSize,
+		NumberOfClusters:         numberOfClusters,
+		UniqueColorSearchRetries: uniqueColorSearchRetries,
+		ConvergenceIterations:    convergenceIterations,
+		MinHSP:                   minHSP,
+		MaxHSP:                   maxHSP,
 	}
 }
@@ -0,0 +1,166 @@
+package dominantcolor
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// elkanPoint tracks the triangle-inequality bounds Elkan's algorithm needs
+// to avoid recomputing a point's distance to every cluster on every pass:
+// upper is an upper bound on the distance to its assigned cluster, and
+// lower[c] is a lower bound on the distance to cluster c.
+type elkanPoint struct {
+	r, g, b  uint8
+	assigned int
+	upper    float64
+	lower    []float64
+}
+
+// clusterElkan fits clusters using Elkan's triangle-inequality variant of
+// k-means: most points can be proven, from bounds alone, to still belong to
+// their current cluster, which skips the distance computation entirely.
+// This is the default algorithm; see clusterNaive for the brute-force
+// original.
+func (d *DominantColor) clusterElkan(img image.Image) kMeanClusterGroup {
+	clusters, img := d.seedClusters(img)
+	k := len(clusters)
+	if k == 0 {
+		return clusters
+	}
+
+	bounds := img.Bounds()
+	var points []elkanPoint
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			ri, gi, bi, a := img.At(x, y).RGBA()
+			// Ignore transparent pixels.
+			if a == 0 {
+				continue
+			}
+			points = append(points, elkanPoint{
+				r: uint8(ri / 255), g: uint8(gi / 255), b: uint8(bi / 255),
+				lower: make([]float64, k),
+			})
+		}
+	}
+
+	// Initial assignment has no bounds to prune with yet, so it's a brute
+	// force pass; it also seeds upper/lower for the pruned iterations below.
+	for i := range points {
+		p := &points[i]
+		best, bestDist := 0, distanceToCluster(clusters[0], p.r, p.g, p.b)
+		p.lower[0] = bestDist
+		for c := 1; c < k; c++ {
+			dist := distanceToCluster(clusters[c], p.r, p.g, p.b)
+			p.lower[c] = dist
+			if dist < bestDist {
+				best, bestDist = c, dist
+			}
+		}
+		p.assigned, p.upper = best, bestDist
+		clusters[best].AddPoint(p.r, p.g, p.b)
+	}
+
+	centerDist := make([][]float64, k)
+	for i := range centerDist {
+		centerDist[i] = make([]float64, k)
+	}
+	s := make([]float64, k)
+
+	convergence := false
+	for iter := 0; iter < d.ConvergenceIterations && !convergence; iter++ {
+		// (1) recompute center-to-center distances and s(c) = 0.5*min_c'!=c d(c,c').
+		for a := 0; a < k; a++ {
+			min := math.Inf(1)
+			for b := 0; b < k; b++ {
+				if a == b {
+					continue
+				}
+				dist := distanceBetweenClusters(clusters[a], clusters[b])
+				centerDist[a][b] = dist
+				if dist < min {
+					min = dist
+				}
+			}
+			if math.IsInf(min, 1) {
+				min = 0
+			}
+			s[a] = 0.5 * min
+		}
+
+		reassigned := false
+		for i := range points {
+			p := &points[i]
+			c := p.assigned
+
+			// (2) a point that's closer to its center than to any other
+			// center's neighborhood can't have a closer cluster.
+			if p.upper <= s[c] {
+				continue
+			}
+
+			tightened := false
+			for cp := 0; cp < k; cp++ {
+				if cp == c {
+					continue
+				}
+				// (3) skip candidates the bounds already rule out.
+				if p.upper <= p.lower[cp] || p.upper <= 0.5*centerDist[c][cp] {
+					continue
+				}
+				if !tightened {
+					// The upper bound was stale; tighten it to the true
+					// distance before testing the remaining candidates.
+					p.upper = distanceToCluster(clusters[c], p.r, p.g, p.b)
+					p.lower[c] = p.upper
+					tightened = true
+					if p.upper <= p.lower[cp] || p.upper <= 0.5*centerDist[c][cp] {
+						continue
+					}
+				}
+				dist := distanceToCluster(clusters[cp], p.r, p.g, p.b)
+				p.lower[cp] = dist
+				if dist < p.upper {
+					c, p.upper = cp, dist
+				}
+			}
+
+			if c != p.assigned {
+				clusters[p.assigned].RemovePoint(p.r, p.g, p.b)
+				clusters[c].AddPoint(p.r, p.g, p.b)
+				p.assigned = c
+				reassigned = true
+			}
+		}
+
+		// (4) recompute centroids and shift every bound by how far its
+		// cluster moved, so bounds stay valid without a full rescan. The fit
+		// has converged once no point was reassigned and no centroid moved.
+		moved := make([]float64, k)
+		convergence = !reassigned
+		for c := range clusters {
+			moved[c] = clusters[c].Recenter()
+			convergence = convergence && moved[c] == 0
+		}
+		for i := range points {
+			p := &points[i]
+			for c := 0; c < k; c++ {
+				p.lower[c] = math.Max(0, p.lower[c]-moved[c])
+			}
+			p.upper += moved[p.assigned]
+		}
+	}
+
+	sort.Sort(byWeight(clusters))
+	return clusters
+}
+
+func distanceToCluster(c *kMeanCluster, r, g, b uint8) float64 {
+	return math.Sqrt(float64(c.distanceSqr(r, g, b)))
+}
+
+func distanceBetweenClusters(a, b *kMeanCluster) float64 {
+	r, g, bl := a.Centroid()
+	return distanceToCluster(b, r, g, bl)
+}
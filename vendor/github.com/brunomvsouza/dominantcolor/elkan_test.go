@@ -0,0 +1,56 @@
+package dominantcolor
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestClusterElkanMatchesNaive fits the same seeded image with both
+// strategies and checks Elkan's pruning doesn't change the result: its
+// bounds are only a performance optimization over clusterNaive's
+// brute-force scan, so the two must converge to the same clusters.
+func TestClusterElkanMatchesNaive(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	blocks := []color.RGBA{
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 0, G: 255, B: 0, A: 255},
+		{R: 0, G: 0, B: 255, A: 255},
+		{R: 255, G: 255, B: 0, A: 255},
+	}
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, blocks[(x/20)*2+y/20])
+		}
+	}
+
+	base := DominantColor{
+		SampleImageSize:          256,
+		NumberOfClusters:         4,
+		UniqueColorSearchRetries: 10,
+		ConvergenceIterations:    50,
+		Seed:                     1,
+	}
+
+	elkan := base
+	elkan.Algorithm = AlgorithmElkan
+	elkanClusters := elkan.cluster(img)
+
+	naive := base
+	naive.Algorithm = AlgorithmNaive
+	naiveClusters := naive.cluster(img)
+
+	if len(elkanClusters) != len(naiveClusters) {
+		t.Fatalf("got %d elkan clusters, %d naive clusters", len(elkanClusters), len(naiveClusters))
+	}
+	for i := range elkanClusters {
+		er, eg, eb := elkanClusters[i].Centroid()
+		nr, ng, nb := naiveClusters[i].Centroid()
+		if er != nr || eg != ng || eb != nb {
+			t.Errorf("cluster %d centroid = (%d,%d,%d), want (%d,%d,%d)", i, er, eg, eb, nr, ng, nb)
+		}
+		if elkanClusters[i].weight != naiveClusters[i].weight {
+			t.Errorf("cluster %d weight = %d, want %d", i, elkanClusters[i].weight, naiveClusters[i].weight)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package dominantcolor
+
+import "math"
+
+// D65 reference white, used to normalize XYZ before converting to CIELAB.
+const (
+	refX = 95.047
+	refY = 100.000
+	refZ = 108.883
+)
+
+// rgbToLab converts an 8-bit sRGB color to CIELAB (D65 illuminant), going
+// through linear RGB and XYZ as intermediate spaces.
+func rgbToLab(r, g, b uint8) (l, a, bb float64) {
+	x, y, z := rgbToXYZ(r, g, b)
+	return xyzToLab(x, y, z)
+}
+
+// labToRGB is the inverse of rgbToLab, rounding back down to 8-bit sRGB.
+func labToRGB(l, a, b float64) (r, g, bb uint8) {
+	x, y, z := labToXYZ(l, a, b)
+	return xyzToRGB(x, y, z)
+}
+
+func rgbToXYZ(r, g, b uint8) (x, y, z float64) {
+	rl := srgbToLinear(float64(r) / 255)
+	gl := srgbToLinear(float64(g) / 255)
+	bl := srgbToLinear(float64(b) / 255)
+
+	x = (rl*0.4124564 + gl*0.3575761 + bl*0.1804375) * 100
+	y = (rl*0.2126729 + gl*0.7151522 + bl*0.0721750) * 100
+	z = (rl*0.0193339 + gl*0.1191920 + bl*0.9503041) * 100
+	return x, y, z
+}
+
+func xyzToRGB(x, y, z float64) (r, g, b uint8) {
+	x, y, z = x/100, y/100, z/100
+
+	rl := x*3.2404542 + y*-1.5371385 + z*-0.4985314
+	gl := x*-0.9692660 + y*1.8760108 + z*0.0415560
+	bl := x*0.0556434 + y*-0.2040259 + z*1.0572252
+
+	return clamp255(linearToSrgb(rl)), clamp255(linearToSrgb(gl)), clamp255(linearToSrgb(bl))
+}
+
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(c float64) float64 {
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func clamp255(c float64) uint8 {
+	v := c * 255
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
+
+func xyzToLab(x, y, z float64) (l, a, b float64) {
+	fx := labF(x / refX)
+	fy := labF(y / refY)
+	fz := labF(z / refZ)
+
+	l = 116*fy - 16
+	a = 500 * (fx - fy)
+	b = 200 * (fy - fz)
+	return l, a, b
+}
+
+func labToXYZ(l, a, b float64) (x, y, z float64) {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+
+	return refX * labFInv(fx), refY * labFInv(fy), refZ * labFInv(fz)
+}
+
+func labF(t float64) float64 {
+	const delta = 6.0 / 29.0
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29.0
+}
+
+func labFInv(t float64) float64 {
+	const delta = 6.0 / 29.0
+	if t > delta {
+		return t * t * t
+	}
+	return 3 * delta * delta * (t - 4.0/29.0)
+}
+
+// labCluster is a k-means cluster whose centroid and running aggregate are
+// kept in CIELAB rather than RGB, so distance reflects perceived color
+// difference (ΔE) instead of raw channel differences.
+type labCluster struct {
+	l, a, b          float64
+	sumL, sumA, sumB float64
+	count            int
+	weight           int
+}
+
+func (c *labCluster) SetCentroid(l, a, b float64) {
+	c.l, c.a, c.b = l, a, b
+}
+
+func (c *labCluster) distanceSqr(l, a, b float64) float64 {
+	dl, da, db := c.l-l, c.a-a, c.b-b
+	return dl*dl + da*da + db*db
+}
+
+func (c *labCluster) AddPoint(l, a, b float64) {
+	c.sumL += l
+	c.sumA += a
+	c.sumB += b
+	c.count++
+}
+
+// RecomputeCentroid recenters the cluster on the mean of the points added
+// since the last call and reports whether the centroid stayed put.
+func (c *labCluster) RecomputeCentroid() (converged bool) {
+	c.weight = c.count
+	if c.count == 0 {
+		return true
+	}
+	newL := c.sumL / float64(c.count)
+	newA := c.sumA / float64(c.count)
+	newB := c.sumB / float64(c.count)
+	converged = newL == c.l && newA == c.a && newB == c.b
+	c.l, c.a, c.b = newL, newA, newB
+	c.sumL, c.sumA, c.sumB, c.count = 0, 0, 0, 0
+	return converged
+}
+
+type labClusterGroup []*labCluster
+
+func (grp labClusterGroup) Closest(l, a, b float64) *labCluster {
+	var closest *labCluster
+	best := math.Inf(1)
+	for _, c := range grp {
+		if d := c.distanceSqr(l, a, b); closest == nil || d < best {
+			closest, best = c, d
+		}
+	}
+	return closest
+}
+
+type byLabWeight labClusterGroup
+
+func (a byLabWeight) Len() int      { return len(a) }
+func (a byLabWeight) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byLabWeight) Less(i, j int) bool {
+	return a[i].weight > a[j].weight
+}
@@ -0,0 +1,117 @@
+package quantize
+
+import "sort"
+
+// MedianCut buckets pixels by repeatedly splitting the box with the widest
+// channel range at the median along that channel, until n boxes remain.
+type MedianCut struct{}
+
+// Quantize implements Quantizer.
+func (MedianCut) Quantize(pixels []Color, n int) []Color {
+	if len(pixels) == 0 || n <= 0 {
+		return nil
+	}
+
+	boxes := []colorBox{{pixels: pixels}}
+	for len(boxes) < n {
+		widest := -1
+		widestRange := 0
+		for i, b := range boxes {
+			if len(b.pixels) < 2 {
+				continue
+			}
+			if _, rng := b.widestChannel(); rng > widestRange {
+				widest, widestRange = i, rng
+			}
+		}
+		if widest == -1 {
+			// Every remaining box holds a single color (zero channel
+			// range); splitting it further would only produce duplicate
+			// buckets, so stop here even if fewer than n boxes remain.
+			break
+		}
+
+		b := boxes[widest]
+		channel, _ := b.widestChannel()
+		sort.Slice(b.pixels, func(i, j int) bool {
+			return channelValue(b.pixels[i], channel) < channelValue(b.pixels[j], channel)
+		})
+		mid := len(b.pixels) / 2
+		boxes[widest] = colorBox{pixels: b.pixels[:mid]}
+		boxes = append(boxes, colorBox{pixels: b.pixels[mid:]})
+	}
+
+	result := make([]Color, 0, len(boxes))
+	for _, b := range boxes {
+		result = append(result, b.mean())
+	}
+	return result
+}
+
+// colorBox is a bucket of pixels not yet split by MedianCut.
+type colorBox struct {
+	pixels []Color
+}
+
+// widestChannel returns which of R(0)/G(1)/B(2) has the largest extent
+// across the box's pixels, and that extent.
+func (b colorBox) widestChannel() (channel, rng int) {
+	minR, maxR := 255, 0
+	minG, maxG := 255, 0
+	minB, maxB := 255, 0
+	for _, p := range b.pixels {
+		minR, maxR = minInt(minR, int(p.R)), maxInt(maxR, int(p.R))
+		minG, maxG = minInt(minG, int(p.G)), maxInt(maxG, int(p.G))
+		minB, maxB = minInt(minB, int(p.B)), maxInt(maxB, int(p.B))
+	}
+
+	channel, rng = 0, maxR-minR
+	if g := maxG - minG; g > rng {
+		channel, rng = 1, g
+	}
+	if bl := maxB - minB; bl > rng {
+		channel, rng = 2, bl
+	}
+	return channel, rng
+}
+
+func (b colorBox) mean() Color {
+	var sumR, sumG, sumB uint64
+	for _, p := range b.pixels {
+		sumR += uint64(p.R)
+		sumG += uint64(p.G)
+		sumB += uint64(p.B)
+	}
+	n := uint64(len(b.pixels))
+	return Color{
+		R:     uint8(sumR / n),
+		G:     uint8(sumG / n),
+		B:     uint8(sumB / n),
+		Count: len(b.pixels),
+	}
+}
+
+func channelValue(c Color, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
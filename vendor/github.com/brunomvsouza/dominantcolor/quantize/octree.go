@@ -0,0 +1,156 @@
+package quantize
+
+// Octree buckets pixels into a color tree of depth 8 (one bit per channel
+// per level, MSB first) and then, while the leaf count exceeds n, folds the
+// least-populated node at the deepest level into a single leaf.
+type Octree struct{}
+
+const octreeDepth = 8
+
+// octreeNode is either an internal node with up to 8 children (one per
+// combination of the next R/G/B bit) or, once isLeaf is set, an aggregate
+// of every pixel folded into it.
+type octreeNode struct {
+	children [8]*octreeNode
+	isLeaf   bool
+	r, g, b  uint64
+	count    int
+}
+
+// populationOf approximates a node's total pixel count from itself and its
+// immediate children, which is all reduce needs since nodes only become
+// reduction candidates once their children are leaves.
+func populationOf(n *octreeNode) int {
+	total := n.count
+	for _, c := range n.children {
+		if c != nil {
+			total += c.count
+		}
+	}
+	return total
+}
+
+// octree is the working state for a single Quantize call: the root plus,
+// per level above the leaves, the internal nodes that own at least one
+// leaf and are therefore candidates for reduce to fold back down.
+type octree struct {
+	root      *octreeNode
+	reducible [octreeDepth - 1][]*octreeNode
+	leafCount int
+}
+
+func (o *octree) insert(p Color) {
+	node := o.root
+	for level := 0; level < octreeDepth; level++ {
+		shift := uint(octreeDepth - 1 - level)
+		idx := ((p.R>>shift)&1)<<2 | ((p.G>>shift)&1)<<1 | (p.B>>shift)&1
+
+		child := node.children[idx]
+		if child == nil {
+			child = &octreeNode{}
+			node.children[idx] = child
+			if level == octreeDepth-1 {
+				child.isLeaf = true
+				o.leafCount++
+			} else {
+				o.reducible[level] = append(o.reducible[level], child)
+			}
+		}
+		node = child
+	}
+	node.r += uint64(p.R)
+	node.g += uint64(p.G)
+	node.b += uint64(p.B)
+	node.count++
+}
+
+// reduce folds the least-populated reducible node at the deepest available
+// level into itself until at most target leaves remain. Once every level
+// below the root is folded, the root itself is folded into one leaf.
+func (o *octree) reduce(target int) {
+	for o.leafCount > target {
+		level := -1
+		for l := octreeDepth - 2; l >= 0; l-- {
+			if len(o.reducible[l]) > 0 {
+				level = l
+				break
+			}
+		}
+
+		var node *octreeNode
+		if level == -1 {
+			if o.root.isLeaf {
+				break
+			}
+			node = o.root
+		} else {
+			nodes := o.reducible[level]
+			best := 0
+			bestPop := populationOf(nodes[0])
+			for i, n := range nodes[1:] {
+				if pop := populationOf(n); pop < bestPop {
+					best, bestPop = i+1, pop
+				}
+			}
+			node = nodes[best]
+			o.reducible[level] = append(nodes[:best], nodes[best+1:]...)
+		}
+
+		removedLeaves := 0
+		for i, c := range node.children {
+			if c == nil {
+				continue
+			}
+			node.r += c.r
+			node.g += c.g
+			node.b += c.b
+			node.count += c.count
+			if c.isLeaf {
+				removedLeaves++
+			}
+			node.children[i] = nil
+		}
+		node.isLeaf = true
+		o.leafCount += 1 - removedLeaves
+	}
+}
+
+func (o *octree) leaves() []Color {
+	var result []Color
+	var walk func(*octreeNode)
+	walk = func(n *octreeNode) {
+		if n == nil {
+			return
+		}
+		if n.isLeaf {
+			if n.count > 0 {
+				result = append(result, Color{
+					R:     uint8(n.r / uint64(n.count)),
+					G:     uint8(n.g / uint64(n.count)),
+					B:     uint8(n.b / uint64(n.count)),
+					Count: n.count,
+				})
+			}
+			return
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(o.root)
+	return result
+}
+
+// Quantize implements Quantizer.
+func (Octree) Quantize(pixels []Color, n int) []Color {
+	if len(pixels) == 0 || n <= 0 {
+		return nil
+	}
+
+	o := &octree{root: &octreeNode{}}
+	for _, p := range pixels {
+		o.insert(p)
+	}
+	o.reduce(n)
+	return o.leaves()
+}
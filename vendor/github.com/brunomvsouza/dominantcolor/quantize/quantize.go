@@ -0,0 +1,20 @@
+// Package quantize provides alternative color-reduction backends for
+// dominantcolor. Unlike the package's default k-means fit, a Quantizer
+// deterministically buckets every sampled pixel into at most N boxes in a
+// single pass, which trades cluster quality for a fixed, seed-independent
+// running time.
+package quantize
+
+// Color is a single sampled pixel, or the weighted mean of a bucket of
+// them once Count is set by a Quantizer.
+type Color struct {
+	R, G, B uint8
+	// Count is the number of pixels the color represents.
+	Count int
+}
+
+// Quantizer reduces a slice of pixels down to at most n representative
+// colors, each carrying the population it was averaged from.
+type Quantizer interface {
+	Quantize(pixels []Color, n int) []Color
+}
@@ -0,0 +1,55 @@
+package quantize
+
+import "testing"
+
+func totalCount(colors []Color) int {
+	total := 0
+	for _, c := range colors {
+		total += c.Count
+	}
+	return total
+}
+
+func TestQuantizersRespectBucketLimitAndConserveCount(t *testing.T) {
+	pixels := []Color{
+		{R: 255, G: 0, B: 0}, {R: 250, G: 5, B: 0}, {R: 245, G: 0, B: 5},
+		{R: 0, G: 255, B: 0}, {R: 5, G: 250, B: 0},
+		{R: 0, G: 0, B: 255}, {R: 0, G: 5, B: 250}, {R: 5, G: 0, B: 245},
+	}
+
+	for _, q := range []Quantizer{MedianCut{}, Octree{}} {
+		result := q.Quantize(pixels, 3)
+		if len(result) > 3 {
+			t.Errorf("%T: got %d buckets, want at most 3", q, len(result))
+		}
+		if got := totalCount(result); got != len(pixels) {
+			t.Errorf("%T: bucket counts sum to %d, want %d", q, got, len(pixels))
+		}
+	}
+}
+
+func TestQuantizersOnUniformInput(t *testing.T) {
+	pixels := make([]Color, 10)
+	for i := range pixels {
+		pixels[i] = Color{R: 100, G: 150, B: 200}
+	}
+
+	for _, q := range []Quantizer{MedianCut{}, Octree{}} {
+		result := q.Quantize(pixels, 4)
+		if len(result) != 1 {
+			t.Fatalf("%T: got %d buckets for uniform input, want 1", q, len(result))
+		}
+		got := result[0]
+		if got.R != 100 || got.G != 150 || got.B != 200 || got.Count != len(pixels) {
+			t.Errorf("%T: got %+v, want {R:100 G:150 B:200 Count:%d}", q, got, len(pixels))
+		}
+	}
+}
+
+func TestQuantizersOnEmptyInput(t *testing.T) {
+	for _, q := range []Quantizer{MedianCut{}, Octree{}} {
+		if result := q.Quantize(nil, 4); result != nil {
+			t.Errorf("%T: got %v for empty input, want nil", q, result)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package dominantcolor
+
+import (
+	"image"
+	"image/color"
+)
+
+// Target describes a named swatch the palette extraction should try to
+// fill, modeled after the Material Design / Android Palette library
+// targets (Vibrant, Muted and their light/dark variants).
+type Target struct {
+	Name string
+	// SaturationTarget and LightnessTarget are the ideal HSL saturation and
+	// lightness for this target, both in the range [0, 1].
+	SaturationTarget, LightnessTarget float64
+}
+
+// DefaultTargets are the six standard Material targets.
+var DefaultTargets = []Target{
+	{Name: "vibrant", SaturationTarget: 1.0, LightnessTarget: 0.5},
+	{Name: "light-vibrant", SaturationTarget: 1.0, LightnessTarget: 0.74},
+	{Name: "dark-vibrant", SaturationTarget: 1.0, LightnessTarget: 0.26},
+	{Name: "muted", SaturationTarget: 0.3, LightnessTarget: 0.5},
+	{Name: "light-muted", SaturationTarget: 0.3, LightnessTarget: 0.74},
+	{Name: "dark-muted", SaturationTarget: 0.3, LightnessTarget: 0.26},
+}
+
+// Weights applied to the three components of a target's score: how far the
+// cluster's saturation and lightness are from the target, and how small
+// its population is relative to the most populous cluster.
+const (
+	saturationWeight = 0.24
+	lightnessWeight  = 0.52
+	populationWeight = 0.24
+)
+
+// Swatches scores every cluster found by the k-means fit against d.Targets
+// (or DefaultTargets if unset) and returns the best-matching color for each
+// target, keyed by Target.Name. A cluster is used for at most one target;
+// targets for which no cluster remains are omitted from the result.
+func (d *DominantColor) Swatches(img image.Image) map[string]color.RGBA {
+	clusters := d.cluster(img)
+	if len(clusters) == 0 {
+		return map[string]color.RGBA{}
+	}
+
+	targets := d.Targets
+	if targets == nil {
+		targets = DefaultTargets
+	}
+
+	maxPop := clusters[0].weight
+	for _, c := range clusters {
+		if c.weight > maxPop {
+			maxPop = c.weight
+		}
+	}
+
+	used := make([]bool, len(clusters))
+	swatches := make(map[string]color.RGBA, len(targets))
+	for _, t := range targets {
+		best := -1
+		var bestScore float64
+		for i, c := range clusters {
+			if used[i] {
+				continue
+			}
+			score := targetScore(c, t, maxPop)
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best == -1 {
+			continue
+		}
+		used[best] = true
+		r, g, b := clusters[best].Centroid()
+		swatches[t.Name] = color.RGBA{R: r, G: g, B: b, A: 0xFF}
+	}
+	return swatches
+}
+
+// targetScore rates how well cluster c matches target t. Higher is better;
+// it is 1 minus the weighted distance between the cluster's HSL saturation
+// and lightness and the target's, plus a penalty for low population.
+func targetScore(c *kMeanCluster, t Target, maxPop int) float64 {
+	r, g, b := c.Centroid()
+	_, s, l := rgbToHSL(r, g, b)
+
+	var popPenalty float64
+	if maxPop > 0 {
+		popPenalty = 1 - float64(c.weight)/float64(maxPop)
+	}
+
+	cost := saturationWeight*absFloat(s-t.SaturationTarget) +
+		lightnessWeight*absFloat(l-t.LightnessTarget) +
+		populationWeight*popPenalty
+	return 1 - cost
+}
+
+func absFloat(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// rgbToHSL converts an 8-bit-per-channel RGB color to hue, saturation and
+// lightness, each in the range [0, 1].
+func rgbToHSL(r, g, b uint8) (h, s, l float64) {
+	rf, gf, bf := float64(r)/255, float64(g)/255, float64(b)/255
+	max := maxFloat(rf, gf, bf)
+	min := minFloat(rf, gf, bf)
+	l = (max + min) / 2
+
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+
+	switch max {
+	case rf:
+		h = (gf - bf) / d
+		if gf < bf {
+			h += 6
+		}
+	case gf:
+		h = (bf-rf)/d + 2
+	case bf:
+		h = (rf-gf)/d + 4
+	}
+	h /= 6
+
+	return h, s, l
+}
+
+func maxFloat(a, b, c float64) float64 {
+	m := a
+	if b > m {
+		m = b
+	}
+	if c > m {
+		m = c
+	}
+	return m
+}
+
+func minFloat(a, b, c float64) float64 {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}